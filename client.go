@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptrace"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/go-server-timing"
 )
@@ -13,6 +15,11 @@ import (
 // KeySource is the key in the metric in which the source name will be stored
 const KeySource = "source"
 
+// KeyParent is the key in a phase sub-metric's Extra that holds its parent
+// metric's name, so downstream consumers can rebuild the tree without
+// parsing it back out of the "parent;phase" naming convention
+const KeyParent = "parent"
+
 // Option is client-timing mockTransport option function
 type Option func(*Client)
 
@@ -53,14 +60,54 @@ func WithUpdate(update func(*servertiming.Metric, *http.Response, error)) Option
 	}
 }
 
+// WithTrace enables or disables per-phase (DNS lookup, TCP connect, TLS
+// handshake, wait-for-first-byte, body read) sub-metrics for every round
+// trip performed by the client, captured with net/http/httptrace. It is
+// disabled by default for zero overhead.
+func WithTrace(enabled bool) Option {
+	return func(t *Client) {
+		t.trace = enabled
+	}
+}
+
+// WithPhaseNamer sets the function used to name phase sub-metrics emitted
+// when WithTrace is enabled. It is called with the parent metric's name and
+// the phase ("dns", "connect", "tls", "ttfb" or "body"). The default namer
+// returns "<parent>;<phase>".
+func WithPhaseNamer(namer func(parent, phase string) string) Option {
+	return func(t *Client) {
+		t.phaseNamer = namer
+	}
+}
+
+// WithRetry enables automatic retries of failed round trips according to
+// policy. Every attempt is recorded as its own child metric, named
+// "<metric>;attempt=<n>", so the full retry timeline is visible in the
+// server-timing header whether the request eventually succeeds or not.
+func WithRetry(policy RetryPolicy) Option {
+	policy.withDefaults()
+	return func(t *Client) {
+		t.retry = &policy
+	}
+}
+
+// WithUserAgent sets the User-Agent header on every request performed by
+// the client, unless the caller already set one
+func WithUserAgent(userAgent string) Option {
+	return func(t *Client) {
+		t.userAgent = userAgent
+	}
+}
+
 // New returns a instrumented constructor for http client and mockTransport.
 func New(opts ...Option) *Client {
 	// create default round tripper
 	t := &Client{
-		inner:  http.DefaultTransport,
-		metric: DefaultMetric,
-		desc:   DefaultDesc,
-		update: DefaultUpdate,
+		inner:      http.DefaultTransport,
+		metric:     DefaultMetric,
+		desc:       DefaultDesc,
+		update:     DefaultUpdate,
+		phaseNamer: defaultPhaseNamer,
 	}
 
 	// apply options
@@ -70,12 +117,15 @@ func New(opts ...Option) *Client {
 	return t
 }
 
-// Transport returns a server-timing instrumented round tripper for the current context
+// Transport returns a server-timing instrumented round tripper for the current context.
+//
+// It shares its instrumentation with Wrap, but takes the timing header from
+// ctx instead of from the request's own context; the request itself,
+// including its context, is passed on unchanged. Prefer Wrap directly when
+// building a long-lived http.RoundTripper chain, since it reads
+// servertiming.FromContext from each request as it comes in.
 func (c *Client) Transport(ctx context.Context) http.RoundTripper {
-	return &transport{
-		Client: *c,
-		timing: servertiming.FromContext(ctx),
-	}
+	return contextTransport{Client: *c, timing: servertiming.FromContext(ctx)}
 }
 
 // Client returns a server-timing instrumented http client for the current context
@@ -83,6 +133,74 @@ func (c *Client) Client(ctx context.Context) *http.Client {
 	return &http.Client{Transport: c.Transport(ctx)}
 }
 
+// Wrap returns an http.RoundTripper that instruments inner with server-timing,
+// reading the timing header from servertiming.FromContext(req.Context()) on
+// every call. Unlike Client.Transport, which is built per request context,
+// Wrap is meant to be built once and composed into a long-lived stack of
+// RoundTripper middlewares:
+//
+//	rt := clienttiming.Wrap(http.DefaultTransport, clienttiming.WithName("my-service"))
+//	rt = auth.Wrap(rt)
+//	rt = retry.Wrap(rt)
+//	client := &http.Client{Transport: rt}
+func Wrap(inner http.RoundTripper, opts ...Option) http.RoundTripper {
+	opts = append([]Option{WithTransport(inner)}, opts...)
+	return New(opts...).wrap()
+}
+
+// Middleware wraps an http.RoundTripper with another, such as Wrap,
+// retry or auth layers, to be composed with Chain
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Chain composes middlewares into a single one. The first middleware is
+// the outermost: Chain(a, b, c)(inner) is equivalent to a(b(c(inner))).
+func Chain(middlewares ...Middleware) Middleware {
+	return func(inner http.RoundTripper) http.RoundTripper {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			inner = middlewares[i](inner)
+		}
+		return inner
+	}
+}
+
+// wrap returns an http.RoundTripper for c that reads the timing header from
+// each request's own context, rather than from a context fixed in advance
+func (c *Client) wrap() http.RoundTripper {
+	return &contextualTransport{Client: *c}
+}
+
+// contextualTransport is the http.RoundTripper returned by Wrap: it reads
+// the timing header from the incoming request's context on every call
+type contextualTransport struct {
+	Client
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *contextualTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr := &transport{
+		Client: t.Client,
+		timing: servertiming.FromContext(req.Context()),
+	}
+	return tr.RoundTrip(req)
+}
+
+// contextTransport is the http.RoundTripper returned by Client.Transport: it
+// records into a timing header fixed in advance, and passes the request on
+// without touching its context
+type contextTransport struct {
+	Client
+	timing *servertiming.Header
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr := &transport{
+		Client: t.Client,
+		timing: t.timing,
+	}
+	return tr.RoundTrip(req)
+}
+
 // Client is instrumented http Client
 type Client struct {
 	// inner is the inner Client used for sending the request and receiving the response
@@ -96,6 +214,23 @@ type Client struct {
 	// name is the name of the service holding the client
 	// it will be added to the timing extra data as "source"
 	name string
+	// trace enables per-phase sub-metrics via httptrace, see WithTrace
+	trace bool
+	// phaseNamer names the phase sub-metrics emitted when trace is
+	// enabled, see WithPhaseNamer
+	phaseNamer func(parent, phase string) string
+	// retry, if set, enables automatic retries with per-attempt metrics,
+	// see WithRetry
+	retry *RetryPolicy
+	// observer, if set, is notified after every round trip so the timing
+	// data can also be forwarded to other telemetry backends
+	observer Observer
+	// notifier, if set, is called after every round trip's metric is
+	// stopped, see WithNotifier
+	notifier Notifier
+	// userAgent, if set, is used as the User-Agent header for every
+	// request that doesn't already have one, see WithUserAgent
+	userAgent string
 }
 
 type transport struct {
@@ -106,9 +241,21 @@ type transport struct {
 
 // RoundTrip implements the http.RoundTripper interface
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.retry != nil {
+		return t.roundTripRetry(req)
+	}
 
+	resp, err, _ := t.roundTripOnce(req, t.metric(req))
+	return resp, err
+}
+
+// roundTripOnce performs a single, instrumented round trip, recording it as
+// a new child metric named name. It returns the metric so callers that need
+// to tag it further, such as roundTripRetry, can do so before it is
+// rendered into the header.
+func (t *transport) roundTripOnce(req *http.Request, name string) (*http.Response, error, *servertiming.Metric) {
 	// Start the metrics for the get
-	metric := t.timing.NewMetric(t.metric(req)).WithDesc(t.desc(req))
+	metric := t.timing.NewMetric(name).WithDesc(t.desc(req))
 
 	if metric.Extra == nil {
 		metric.Extra = make(map[string]string)
@@ -118,6 +265,24 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if t.name != "" {
 		metric.Extra[KeySource] = t.name
 	}
+
+	// install an httptrace.ClientTrace to capture per-phase timestamps if
+	// tracing was requested
+	var pt *phaseTrace
+	if t.trace {
+		pt = &phaseTrace{}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), pt.newClientTrace()))
+	}
+	// set a default User-Agent if the caller didn't already set one; clone
+	// the request first so this never mutates the caller's original request,
+	// per the http.RoundTripper contract
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	start := time.Now()
+
 	metric.Start()
 
 	// Run the inner round trip
@@ -126,18 +291,50 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Stop the metric after get
 	metric.Stop()
 
+	// notify the notifier, if any, right after the metric was stopped
+	if t.notifier != nil {
+		t.notifier.Notify(metric, req, resp, err)
+	}
+
 	// update the metric with the response and error of the request
 	t.update(metric, resp, err)
 
+	// notify the observer, if any, so it can forward the timing data to
+	// other telemetry backends
+	t.observe(metric, req, resp, err)
+
+	// emit the per-phase sub-metrics, if tracing was requested
+	var namer func(parent, phase string) string
+	if pt != nil {
+		namer = t.phaseNamer
+		if namer == nil {
+			namer = defaultPhaseNamer
+		}
+		t.timing.Metrics = append(t.timing.Metrics, pt.metrics(metric, start, namer, err)...)
+	}
+
 	// In case of round trip error, return it
 	if err != nil {
-		return nil, err
+		return nil, err, metric
+	}
+
+	// wrap the body so the "body" phase, whose duration depends on when the
+	// caller is done reading it, is emitted once it is closed. Fall back to
+	// start if GotFirstResponseByte never fired, so a round trip with no
+	// body (or some other edge case that skips that hook) doesn't report a
+	// nonsensical multi-decade duration from the zero time.
+	if pt != nil {
+		from := pt.gotFirstByte
+		if from.IsZero() {
+			from = start
+		}
+		wrapBody(resp, t.timing, metric, from, namer)
 	}
 
 	// Insert the timing headers from the response to the current headers
 	InsertMetrics(t.timing, resp.Header)
 
-	return resp, err
+	return resp, err, metric
 }
 
 // InsertMetrics inserts to servertiming header metrics from an HTTP header of another request
@@ -0,0 +1,25 @@
+package clienttiming
+
+import (
+	"net/http"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// WithObserver sets an Observer that is notified after every round trip
+// performed by the client, in addition to the server-timing header that is
+// always populated
+func WithObserver(o Observer) Option {
+	return func(t *Client) {
+		t.observer = o
+	}
+}
+
+// observe notifies t.observer, if set, with the outcome of a completed
+// round trip
+func (t *transport) observe(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error) {
+	if t.observer == nil {
+		return
+	}
+	t.observer.Observe(metric, req, resp, err)
+}
@@ -0,0 +1,61 @@
+package clienttiming
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	metric *servertiming.Metric
+	req    *http.Request
+	resp   *http.Response
+	err    error
+}
+
+func (o *recordingObserver) Observe(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error) {
+	o.metric, o.req, o.resp, o.err = metric, req, resp, err
+}
+
+func TestWithObserver(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	tr.On("RoundTrip", req).Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	obs := &recordingObserver{}
+	client := New(WithTransport(tr), WithName("my-service"), WithObserver(obs))
+
+	var header servertiming.Header
+	_, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.Nil(t, err)
+
+	assert.Equal(t, "golang.org", obs.metric.Name)
+	assert.Equal(t, "my-service", obs.metric.Extra[KeySource])
+	assert.Equal(t, req, obs.req)
+	assert.Equal(t, http.StatusOK, obs.resp.StatusCode)
+	assert.Nil(t, obs.err)
+}
+
+func TestWithObserverOnError(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	tr.On("RoundTrip", req).Return(nil, fmt.Errorf("boom")).Once()
+
+	obs := &recordingObserver{}
+	client := New(WithTransport(tr), WithObserver(obs))
+
+	var header servertiming.Header
+	_, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.NotNil(t, err)
+
+	assert.Nil(t, obs.resp)
+	assert.EqualError(t, obs.err, "boom")
+}
@@ -0,0 +1,122 @@
+package clienttiming
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// defaultPhaseNamer is the default naming function used by WithPhaseNamer
+func defaultPhaseNamer(parent, phase string) string {
+	return parent + ";" + phase
+}
+
+// phaseTrace collects the timestamps of a single round trip's phases, for
+// use by WithTrace
+type phaseTrace struct {
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotFirstByte              time.Time
+}
+
+// newClientTrace builds an httptrace.ClientTrace that records its
+// timestamps into pt
+func (pt *phaseTrace) newClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { pt.dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { pt.dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { pt.connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { pt.connectDone = time.Now() },
+		TLSHandshakeStart:    func() { pt.tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { pt.tlsDone = time.Now() },
+		GotFirstResponseByte: func() { pt.gotFirstByte = time.Now() },
+	}
+}
+
+// metrics returns a sub-metric for every phase that started, named with
+// namer(parent.Name, phase). A phase that started but never finished (e.g.
+// the round trip failed mid-handshake) is still emitted, with a zero
+// duration and the error recorded in its Extra["error"]; a phase that never
+// started at all, such as DNS/connect/TLS on a reused connection, is
+// omitted.
+func (pt *phaseTrace) metrics(parent *servertiming.Metric, start time.Time, namer func(parent, phase string) string, err error) []*servertiming.Metric {
+	var metrics []*servertiming.Metric
+
+	add := func(phase string, from, to time.Time) {
+		if from.IsZero() {
+			return
+		}
+		m := &servertiming.Metric{
+			Name:  namer(parent.Name, phase),
+			Extra: make(map[string]string),
+		}
+		if source := parent.Extra[KeySource]; source != "" {
+			m.Extra[KeySource] = source
+		}
+		m.Extra[KeyParent] = parent.Name
+		if to.IsZero() {
+			if err != nil {
+				m.Extra["error"] = err.Error()
+			}
+		} else {
+			m.Duration = to.Sub(from)
+		}
+		metrics = append(metrics, m)
+	}
+
+	add("dns", pt.dnsStart, pt.dnsDone)
+	add("connect", pt.connectStart, pt.connectDone)
+	add("tls", pt.tlsStart, pt.tlsDone)
+	add("ttfb", start, pt.gotFirstByte)
+
+	return metrics
+}
+
+// wrapBody wraps resp.Body so that closing it emits a "body" sub-metric
+// measuring the time from the first response byte until the body was
+// closed, named and sourced the same way as the other phases. It must be
+// called instead of pt.metrics appending the body phase directly, since the
+// duration isn't known until the caller is done reading the response.
+func wrapBody(resp *http.Response, timing *servertiming.Header, parent *servertiming.Metric, from time.Time, namer func(parent, phase string) string) {
+	resp.Body = &phaseBody{
+		ReadCloser: resp.Body,
+		timing:     timing,
+		parent:     parent,
+		from:       from,
+		namer:      namer,
+	}
+}
+
+// phaseBody wraps a response body, appending a "body" phase sub-metric to
+// timing exactly once, when the body is closed
+type phaseBody struct {
+	io.ReadCloser
+	timing *servertiming.Header
+	parent *servertiming.Metric
+	from   time.Time
+	namer  func(parent, phase string) string
+	once   sync.Once
+}
+
+// Close implements io.Closer
+func (b *phaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		m := &servertiming.Metric{
+			Name:     b.namer(b.parent.Name, "body"),
+			Duration: time.Since(b.from),
+			Extra:    map[string]string{KeyParent: b.parent.Name},
+		}
+		if source := b.parent.Extra[KeySource]; source != "" {
+			m.Extra[KeySource] = source
+		}
+		b.timing.Metrics = append(b.timing.Metrics, m)
+	})
+	return err
+}
@@ -0,0 +1,137 @@
+package clienttiming
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseTraceMetrics(t *testing.T) {
+	t.Parallel()
+
+	parent := &servertiming.Metric{Name: "example.com", Extra: map[string]string{KeySource: "test"}}
+	start := time.Unix(0, 0)
+
+	t.Run("full trace", func(t *testing.T) {
+		pt := &phaseTrace{
+			dnsStart:     start,
+			dnsDone:      start.Add(time.Millisecond),
+			connectStart: start.Add(time.Millisecond),
+			connectDone:  start.Add(2 * time.Millisecond),
+			tlsStart:     start.Add(2 * time.Millisecond),
+			tlsDone:      start.Add(3 * time.Millisecond),
+			gotFirstByte: start.Add(4 * time.Millisecond),
+		}
+
+		metrics := pt.metrics(parent, start, defaultPhaseNamer, nil)
+
+		var names []string
+		for _, m := range metrics {
+			names = append(names, m.Name)
+			assert.Equal(t, "test", m.Extra[KeySource])
+			assert.Equal(t, "example.com", m.Extra[KeyParent])
+		}
+		assert.Equal(t, []string{"example.com;dns", "example.com;connect", "example.com;tls", "example.com;ttfb"}, names)
+	})
+
+	t.Run("reused connection omits dns/connect/tls", func(t *testing.T) {
+		pt := &phaseTrace{gotFirstByte: start.Add(time.Millisecond)}
+
+		metrics := pt.metrics(parent, start, defaultPhaseNamer, nil)
+
+		var names []string
+		for _, m := range metrics {
+			names = append(names, m.Name)
+		}
+		assert.Equal(t, []string{"example.com;ttfb"}, names)
+	})
+
+	t.Run("failure mid-handshake records error on the unfinished phase", func(t *testing.T) {
+		pt := &phaseTrace{
+			dnsStart: start,
+			dnsDone:  start.Add(time.Millisecond),
+			tlsStart: start.Add(time.Millisecond),
+		}
+		err := fmt.Errorf("handshake failed")
+
+		metrics := pt.metrics(parent, start, defaultPhaseNamer, err)
+
+		var tls *servertiming.Metric
+		for _, m := range metrics {
+			if m.Name == "example.com;tls" {
+				tls = m
+			}
+		}
+		if assert.NotNil(t, tls) {
+			assert.Equal(t, "handshake failed", tls.Extra["error"])
+			assert.Zero(t, tls.Duration)
+		}
+	})
+}
+
+func TestWrapBody(t *testing.T) {
+	t.Parallel()
+
+	parent := &servertiming.Metric{Name: "example.com", Extra: map[string]string{KeySource: "test"}}
+	var timing servertiming.Header
+
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader("hello"))}
+	wrapBody(resp, &timing, parent, time.Now(), defaultPhaseNamer)
+
+	// the body metric is only emitted once the caller is done reading
+	assert.Empty(t, timing.Metrics)
+
+	b, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	require.Nil(t, resp.Body.Close())
+	require.Len(t, timing.Metrics, 1)
+	assert.Equal(t, "example.com;body", timing.Metrics[0].Name)
+	assert.Equal(t, "test", timing.Metrics[0].Extra[KeySource])
+	assert.Equal(t, "example.com", timing.Metrics[0].Extra[KeyParent])
+
+	// closing again must not append a second metric
+	require.Nil(t, resp.Body.Close())
+	assert.Len(t, timing.Metrics, 1)
+}
+
+func TestRoundTripOnceBodyPhaseFallsBackWhenNoFirstByte(t *testing.T) {
+	t.Parallel()
+
+	// a mockTransport never drives the httptrace hooks registered by
+	// WithTrace, so gotFirstByte stays zero for the whole round trip; the
+	// body phase must still get a sane duration, not one measured from the
+	// zero time
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	tr.On("RoundTrip", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("hi")),
+	}, nil).Once()
+
+	client := New(WithTransport(tr), WithTrace(true))
+
+	var header servertiming.Header
+	resp, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.Nil(t, err)
+	require.Nil(t, resp.Body.Close())
+
+	var body *servertiming.Metric
+	for _, m := range header.Metrics {
+		if strings.HasSuffix(m.Name, ";body") {
+			body = m
+		}
+	}
+	if assert.NotNil(t, body) {
+		assert.Less(t, body.Duration, time.Second)
+	}
+}
@@ -0,0 +1,49 @@
+package clienttiming
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	tr.On("RoundTrip", mock.MatchedBy(func(r *http.Request) bool {
+		return r.Header.Get("User-Agent") == "my-agent/1.0"
+	})).Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	client := New(WithTransport(tr), WithUserAgent("my-agent/1.0"))
+
+	var header servertiming.Header
+	_, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.Nil(t, err)
+
+	tr.AssertExpectations(t)
+	assert.Empty(t, req.Header.Get("User-Agent"), "the caller's original request must not be mutated")
+}
+
+func TestWithUserAgentDoesNotOverrideExisting(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	req.Header.Set("User-Agent", "caller-agent/2.0")
+	tr.On("RoundTrip", mock.MatchedBy(func(r *http.Request) bool {
+		return r.Header.Get("User-Agent") == "caller-agent/2.0"
+	})).Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	client := New(WithTransport(tr), WithUserAgent("my-agent/1.0"))
+
+	var header servertiming.Header
+	_, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.Nil(t, err)
+
+	tr.AssertExpectations(t)
+}
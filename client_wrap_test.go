@@ -0,0 +1,67 @@
+package clienttiming
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+
+	var header servertiming.Header
+	req = req.WithContext(servertiming.NewContext(req.Context(), &header))
+
+	tr.On("RoundTrip", req).Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	rt := Wrap(tr, WithName("wrapped"))
+	_, err := rt.RoundTrip(req)
+	require.Nil(t, err)
+
+	clearTimes(t, header.Metrics)
+	assert.Equal(t, []*servertiming.Metric{
+		{Name: "golang.org", Desc: "GET ", Extra: map[string]string{"code": "200", "source": "wrapped"}},
+	}, header.Metrics)
+
+	tr.AssertExpectations(t)
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+
+	mw := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	inner := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		order = append(order, "inner")
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	rt := Chain(mw("a"), mw("b"))(inner)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	_, err := rt.RoundTrip(req)
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"a", "b", "inner"}, order)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
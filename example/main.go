@@ -14,8 +14,8 @@ func main() {
 
 	s1 := httptest.NewServer(servertiming.Middleware(
 		&handler{
-			timer: clienttiming.New(clienttiming.WithName("server1")),
-			name:  "server1",
+			client: clienttiming.New(clienttiming.WithName("server1")),
+			name:   "server1",
 		},
 		nil,
 	))
@@ -24,7 +24,7 @@ func main() {
 	s2 := httptest.NewServer(servertiming.Middleware(
 		&handler{
 			name:     "server2",
-			timer:    clienttiming.New(clienttiming.WithName("server2")),
+			client:   clienttiming.New(clienttiming.WithName("server2")),
 			requests: []string{s1.URL + "/level2"},
 		},
 		nil,
@@ -33,8 +33,8 @@ func main() {
 
 	h := servertiming.Middleware(
 		&handler{
-			name:  "handler",
-			timer: clienttiming.New(clienttiming.WithName("handler")),
+			name:   "handler",
+			client: clienttiming.New(clienttiming.WithName("handler")),
 			requests: []string{
 				s1.URL + "/level1",
 				s2.URL + "/level1",
@@ -51,14 +51,14 @@ func main() {
 
 type handler struct {
 	name string
-	// timer is used by te handler to send http requests
-	timer *clienttiming.Timer
+	// client is used by te handler to send http requests
+	client *clienttiming.Client
 	// requests defines addresses for upstream GET requests
 	requests []string
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	c := h.timer.Client(r.Context())
+	c := h.client.Client(r.Context())
 
 	// sleep to have some duration in headers
 	time.Sleep(time.Millisecond * 50)
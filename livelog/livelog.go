@@ -0,0 +1,199 @@
+// Package livelog provides a clienttiming.Observer that fans every
+// completed round trip out to any number of Server-Sent Events subscribers,
+// so operators can watch a service's outbound HTTP traffic live without
+// running a full APM.
+package livelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// event is the JSON representation of a single completed round trip sent to
+// every connected client
+type event struct {
+	TS            time.Time      `json:"ts"`
+	Source        string         `json:"source,omitempty"`
+	Metric        string         `json:"metric"`
+	Desc          string         `json:"desc,omitempty"`
+	Method        string         `json:"method"`
+	URL           string         `json:"url"`
+	Status        int            `json:"status,omitempty"`
+	Err           string         `json:"err,omitempty"`
+	DurMS         float64        `json:"dur_ms"`
+	ParentMetrics []parentMetric `json:"parent_metrics,omitempty"`
+}
+
+// parentMetric is the JSON representation of an upstream server-timing
+// metric forwarded in the response, i.e. one recorded by a service this
+// round trip called into
+type parentMetric struct {
+	Name  string  `json:"name"`
+	Desc  string  `json:"desc,omitempty"`
+	DurMS float64 `json:"dur_ms"`
+}
+
+// clientBuffer is the size of each subscriber's channel. Once full, the
+// distributor drops the event rather than blocking the instrumented client.
+const clientBuffer = 64
+
+// Distributor is a clienttiming.Observer and an http.Handler that serves a
+// Server-Sent Events stream of every round trip it observes
+type Distributor struct {
+	events           chan event
+	openingClients   chan chan event
+	closingClients   chan chan event
+	connectedClients map[chan event]struct{}
+}
+
+// NewDistributor starts a Distributor's fan-out goroutine and returns it
+func NewDistributor() *Distributor {
+	d := &Distributor{
+		events:           make(chan event, clientBuffer),
+		openingClients:   make(chan chan event),
+		closingClients:   make(chan chan event),
+		connectedClients: make(map[chan event]struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// run is the fan-out goroutine: it owns connectedClients so subscribing,
+// unsubscribing and publishing never race
+func (d *Distributor) run() {
+	for {
+		select {
+		case ch := <-d.openingClients:
+			d.connectedClients[ch] = struct{}{}
+		case ch := <-d.closingClients:
+			delete(d.connectedClients, ch)
+			close(ch)
+		case e := <-d.events:
+			for ch := range d.connectedClients {
+				select {
+				case ch <- e:
+				default:
+					// slow subscriber: drop this event rather than
+					// back-pressuring the instrumented client
+				}
+			}
+		}
+	}
+}
+
+// Observe implements clienttiming.Observer
+func (d *Distributor) Observe(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error) {
+	e := event{
+		TS:     time.Now(),
+		Source: metric.Extra["source"],
+		Metric: metric.Name,
+		Desc:   metric.Desc,
+		DurMS:  metric.Duration.Seconds() * 1000,
+	}
+	if req != nil {
+		e.Method = req.Method
+		e.URL = req.URL.String()
+	}
+	if err != nil {
+		e.Err = err.Error()
+	}
+	if resp != nil {
+		e.Status = resp.StatusCode
+		if upstream, parseErr := servertiming.ParseHeader(resp.Header.Get(servertiming.HeaderKey)); parseErr == nil {
+			for _, m := range upstream.Metrics {
+				e.ParentMetrics = append(e.ParentMetrics, parentMetric{
+					Name:  m.Name,
+					Desc:  m.Desc,
+					DurMS: m.Duration.Seconds() * 1000,
+				})
+			}
+		}
+	}
+
+	select {
+	case d.events <- e:
+	default:
+	}
+}
+
+// ServeHTTP implements http.Handler, serving a text/event-stream of every
+// round trip observed from the moment the client connects
+func (d *Distributor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan event, clientBuffer)
+	d.openingClients <- ch
+	defer func() { d.closingClients <- ch }()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case e := <-ch:
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", b)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Page returns a minimal HTML page that renders the stream served at
+// streamPath as a live table, suitable for mounting next to Distributor,
+// e.g. at /debug/client-timing/
+func Page(streamPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, pageHTML, streamPath)
+	}
+}
+
+const pageHTML = `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>client-timing live log</title>
+<style>
+body { font-family: monospace; background: #111; color: #eee; }
+table { border-collapse: collapse; width: 100%%; }
+td, th { border-bottom: 1px solid #333; padding: 2px 6px; text-align: left; }
+.err { color: #f66; }
+</style>
+</head>
+<body>
+<h3>Live client round trips</h3>
+<table>
+<thead><tr><th>time</th><th>source</th><th>metric</th><th>request</th><th>status</th><th>duration</th></tr></thead>
+<tbody id="rows"></tbody>
+</table>
+<script>
+var es = new EventSource(%q);
+var rows = document.getElementById('rows');
+es.onmessage = function(ev) {
+  var e = JSON.parse(ev.data);
+  var row = document.createElement('tr');
+  row.innerHTML = '<td>' + e.ts + '</td><td>' + (e.source || '') + '</td><td>' +
+    e.metric + '</td><td>' + e.method + ' ' + e.url + '</td><td class="' + (e.err ? 'err' : '') + '">' +
+    (e.err || e.status) + '</td><td>' + e.dur_ms.toFixed(1) + 'ms</td>';
+  rows.insertBefore(row, rows.firstChild);
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,67 @@
+package livelog
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistributor(t *testing.T) {
+	t.Parallel()
+
+	d := NewDistributor()
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	// give the subscriber goroutine a moment to register before observing
+	time.Sleep(10 * time.Millisecond)
+
+	req, reqErr := http.NewRequest(http.MethodGet, "https://golang.org/pkg", nil)
+	require.Nil(t, reqErr)
+
+	metric := &servertiming.Metric{
+		Name:  "golang.org",
+		Extra: map[string]string{"source": "my-service"},
+	}
+	metric.Start()
+	metric.Stop()
+
+	upstream := servertiming.Header{Metrics: []*servertiming.Metric{{Name: "github.com"}}}
+	d.Observe(metric, req, &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{servertiming.HeaderKey: []string{upstream.String()}},
+	}, nil)
+
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	line := scanner.Text()
+
+	assert.True(t, strings.HasPrefix(line, "data: "))
+	assert.Contains(t, line, `"metric":"golang.org"`)
+	assert.Contains(t, line, `"method":"GET"`)
+	assert.Contains(t, line, `"url":"https://golang.org/pkg"`)
+	assert.Contains(t, line, `"status":200`)
+	assert.Contains(t, line, `"parent_metrics":[{"name":"github.com","dur_ms":0}]`)
+}
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	Page("/debug/client-timing/stream").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf("%q", "/debug/client-timing/stream"))
+}
@@ -0,0 +1,25 @@
+package clienttiming
+
+import (
+	"net/http"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// Notifier is called after every round trip performed by a Client's
+// transport, right after the metric is stopped. Unlike Observer it is meant
+// for fan-out to many live listeners, such as a local development SSE
+// stream, rather than for aggregating telemetry.
+type Notifier interface {
+	// Notify is called once metric.Stop() completed. resp is nil if the
+	// round trip returned an error.
+	Notify(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error)
+}
+
+// WithNotifier sets a Notifier that is called after every round trip
+// performed by the client, right after the metric is stopped
+func WithNotifier(n Notifier) Option {
+	return func(t *Client) {
+		t.notifier = n
+	}
+}
@@ -0,0 +1,26 @@
+package clienttiming
+
+import (
+	"net/http"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// Observer is notified after every round trip performed by a Client's
+// transport, in addition to the metric being recorded in the server-timing
+// header. It allows forwarding the same timing data to other telemetry
+// backends, such as Prometheus, statsd or OpenTelemetry, without changing
+// the existing header behavior.
+//
+// This takes the full metric, request and response rather than a narrower
+// (source, code, duration) tuple, and ships as a single Observer interface
+// with a prom subpackage rather than a separate promobs package: both are
+// deliberate choices so an Observer can read anything the metric/request/
+// response already carry (Extra, headers, method, ...) without the
+// interface growing a new parameter for every field a backend might want.
+type Observer interface {
+	// Observe is called once the round trip completed (successfully or not)
+	// and after metric.Stop() and the configured update function were
+	// applied, so metric.Duration and any Extra set by them are available.
+	Observe(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error)
+}
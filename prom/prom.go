@@ -0,0 +1,101 @@
+// Package prom provides a clienttiming.Observer that records every client
+// round trip as Prometheus metrics, so the same instrumentation that
+// populates the server-timing header can also feed long term aggregation
+// and alerting.
+package prom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/posener/client-timing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errClass is the name of the label holding the low cardinality error class
+// that classify derives from a round trip's error
+const errClass = "error_class"
+
+// Observer is a clienttiming.Observer that records round trip durations and
+// counts into Prometheus metrics
+type Observer struct {
+	duration  *prometheus.HistogramVec
+	total     *prometheus.CounterVec
+	pathLabel func(*http.Request) string
+}
+
+// Option configures an Observer constructed by New or Register
+type Option func(*Observer)
+
+// WithPathLabel sets the function used to derive the "path" label from the
+// request. The default omits the path entirely, since req.URL.Path is
+// typically unbounded (IDs, slugs, etc.) and would blow up the cardinality
+// of every series below. Callers that have a low-cardinality path template
+// available, e.g. from their router, should supply it here instead.
+func WithPathLabel(f func(*http.Request) string) Option {
+	return func(o *Observer) {
+		o.pathLabel = f
+	}
+}
+
+// New returns an Observer with default histograms and counters. Use Register
+// to install it, along with its metrics, into a Prometheus registry.
+func New(opts ...Option) *Observer {
+	o := &Observer{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "client_request_duration_seconds",
+			Help:    "Duration of outgoing HTTP requests performed through clienttiming, in seconds.",
+			Buckets: []float64{.001, .002, .005, .01, .02, .05, .1, .2, .5, 1, 2, 5, 10},
+		}, []string{"metric", "method", "path", "code", "source"}),
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "client_requests_total",
+			Help: "Total number of outgoing HTTP requests performed through clienttiming, by error class.",
+		}, []string{"metric", "method", "path", "source", errClass}),
+		pathLabel: func(*http.Request) string { return "" },
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Register installs the observer's metrics into reg and returns the
+// observer, so it can be chained into clienttiming.WithObserver
+func Register(reg *prometheus.Registry, opts ...Option) *Observer {
+	o := New(opts...)
+	reg.MustRegister(o.duration, o.total)
+	return o
+}
+
+// Observe implements clienttiming.Observer
+func (o *Observer) Observe(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error) {
+	source := metric.Extra[clienttiming.KeySource]
+	code, class := classify(resp, err)
+	path := o.pathLabel(req)
+
+	o.duration.WithLabelValues(metric.Name, req.Method, path, code, source).Observe(metric.Duration.Seconds())
+	o.total.WithLabelValues(metric.Name, req.Method, path, source, class).Inc()
+}
+
+// classify returns the response status code (or "" if there was none) and a
+// low cardinality error class: "ok", "timeout", "canceled" or "error"
+func classify(resp *http.Response, err error) (code, class string) {
+	if err == nil {
+		return strconv.Itoa(resp.StatusCode), "ok"
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "", "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "", "timeout"
+	default:
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "", "timeout"
+		}
+		return "", "error"
+	}
+}
@@ -0,0 +1,78 @@
+package prom
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/posener/client-timing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+
+	o := Register(prometheus.NewRegistry())
+
+	req, err := http.NewRequest(http.MethodGet, "https://golang.org/pkg", nil)
+	require.Nil(t, err)
+
+	metric := &servertiming.Metric{
+		Name:  "golang.org",
+		Extra: map[string]string{clienttiming.KeySource: "test"},
+	}
+	metric.Start()
+	metric.Stop()
+
+	o.Observe(metric, req, &http.Response{StatusCode: http.StatusOK}, nil)
+
+	assert.Equal(t, 1, testutilCollect(o.total))
+
+	o.Observe(metric, req, nil, fmt.Errorf("boom"))
+
+	assert.Equal(t, 2, testutilCollect(o.total))
+}
+
+func TestObserverWithPathLabel(t *testing.T) {
+	t.Parallel()
+
+	o := New(WithPathLabel(func(r *http.Request) string { return "/pkg" }))
+
+	req, err := http.NewRequest(http.MethodGet, "https://golang.org/pkg", nil)
+	require.Nil(t, err)
+
+	metric := &servertiming.Metric{Name: "golang.org"}
+	metric.Start()
+	metric.Stop()
+
+	o.Observe(metric, req, &http.Response{StatusCode: http.StatusOK}, nil)
+
+	counter, err := o.total.GetMetricWithLabelValues("golang.org", http.MethodGet, "/pkg", "", "ok")
+	require.Nil(t, err)
+	assert.Equal(t, float64(1), testutilCounterValue(counter))
+}
+
+// testutilCounterValue reads back the current value of a single counter,
+// avoiding a dependency on prometheus/client_golang/testutil
+func testutilCounterValue(c prometheus.Counter) float64 {
+	var m dto.Metric
+	_ = c.Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+// testutilCollect returns the number of child metrics currently held by a
+// CounterVec, avoiding a dependency on prometheus/client_golang/testutil
+func testutilCollect(c *prometheus.CounterVec) int {
+	ch := make(chan prometheus.Metric, 10)
+	c.Collect(ch)
+	close(ch)
+	n := 0
+	for range ch {
+		n++
+	}
+	return n
+}
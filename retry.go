@@ -0,0 +1,163 @@
+package clienttiming
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// RetryPolicy configures WithRetry's automatic retry behavior
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a single request,
+	// including the first one. Defaults to 3.
+	MaxAttempts int
+	// InitialInterval is the backoff before the second attempt. Defaults
+	// to 200ms.
+	InitialInterval time.Duration
+	// Multiplier is applied to the interval after every attempt. Defaults
+	// to 2.
+	Multiplier float64
+	// RandomizationFactor jitters each interval by +/- this fraction of
+	// itself. Defaults to 0.5.
+	RandomizationFactor float64
+	// MaxInterval caps the backoff interval. Defaults to 10s.
+	MaxInterval time.Duration
+	// MaxElapsedTime bounds the total time spent retrying a request. Zero
+	// means no limit.
+	MaxElapsedTime time.Duration
+	// Retryable decides whether a completed attempt should be retried.
+	// Defaults to DefaultRetryable.
+	Retryable func(*http.Response, error) bool
+}
+
+// withDefaults fills the zero-valued fields of p with their defaults
+func (p *RetryPolicy) withDefaults() {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialInterval == 0 {
+		p.InitialInterval = 200 * time.Millisecond
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = 2
+	}
+	if p.RandomizationFactor == 0 {
+		p.RandomizationFactor = 0.5
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = 10 * time.Second
+	}
+	if p.Retryable == nil {
+		p.Retryable = DefaultRetryable
+	}
+}
+
+// DefaultRetryable retries on network errors and on 429 or 5xx responses
+func DefaultRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff returns the interval to wait before attempt n+1, jittered by
+// RandomizationFactor and capped at MaxInterval
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	interval := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(n-1))
+	if max := float64(p.MaxInterval); interval > max {
+		interval = max
+	}
+	delta := p.RandomizationFactor * interval
+	interval += delta*2*rand.Float64() - delta
+	if interval < 0 {
+		interval = 0
+	}
+	return time.Duration(interval)
+}
+
+// roundTripRetry performs req, retrying according to t.retry. Every attempt
+// is recorded as a child metric "<base>;attempt=<n>" with Extra["attempt"]
+// set, so the full retry timeline survives in the header regardless of the
+// final outcome.
+func (t *transport) roundTripRetry(req *http.Request) (*http.Response, error) {
+	policy := t.retry
+	base := t.metric(req)
+
+	// a request whose body cannot be re-sent must not be retried past the
+	// first attempt, since it has already been consumed
+	canReplay := req.Body == nil || req.GetBody != nil
+
+	started := time.Now()
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr == nil {
+				attemptReq = req.Clone(req.Context())
+				attemptReq.Body = body
+			}
+		}
+
+		var metric *servertiming.Metric
+		resp, err, metric = t.roundTripOnce(attemptReq, fmt.Sprintf("%s;attempt=%d", base, attempt))
+		metric.Extra["attempt"] = strconv.Itoa(attempt)
+
+		if !policy.Retryable(resp, err) || attempt >= policy.MaxAttempts || !canReplay {
+			return resp, err
+		}
+
+		wait := policy.backoff(attempt)
+		if ra, ok := retryAfter(resp); ok {
+			wait = ra
+		}
+		if policy.MaxElapsedTime > 0 && time.Since(started)+wait > policy.MaxElapsedTime {
+			return resp, err
+		}
+
+		// this attempt is being discarded in favor of a retry: drain and
+		// close its body so the connection can be reused, rather than
+		// leaking it back into the pool unusable
+		if resp != nil && resp.Body != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header from resp, supporting both
+// delta-seconds and HTTP-date forms
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := time.ParseDuration(v + "s"); err == nil {
+		return secs, true
+	}
+	if date, err := http.ParseTime(v); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
@@ -0,0 +1,87 @@
+package clienttiming
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRetryable(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, DefaultRetryable(nil, assert.AnError))
+	assert.True(t, DefaultRetryable(&http.Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assert.True(t, DefaultRetryable(&http.Response{StatusCode: http.StatusInternalServerError}, nil))
+	assert.False(t, DefaultRetryable(&http.Response{StatusCode: http.StatusOK}, nil))
+	assert.False(t, DefaultRetryable(&http.Response{StatusCode: http.StatusNotFound}, nil))
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	_, ok := retryAfter(&http.Response{Header: http.Header{}})
+	assert.False(t, ok)
+
+	d, ok := retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{"2"}}})
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	date := time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat)
+	d, ok = retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{date}}})
+	require.True(t, ok)
+	assert.InDelta(t, 3*time.Second, d, float64(time.Second))
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+
+	tr.On("RoundTrip", req).Return(&http.Response{StatusCode: http.StatusInternalServerError}, nil).Once()
+	tr.On("RoundTrip", req).Return(&http.Response{StatusCode: http.StatusOK}, nil).Once()
+
+	client := New(
+		WithTransport(tr),
+		WithRetry(RetryPolicy{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}),
+	)
+
+	var header servertiming.Header
+	resp, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, header.Metrics, 2)
+	assert.Equal(t, "golang.org;attempt=1", header.Metrics[0].Name)
+	assert.Equal(t, "1", header.Metrics[0].Extra["attempt"])
+	assert.Equal(t, "golang.org;attempt=2", header.Metrics[1].Name)
+	assert.Equal(t, "2", header.Metrics[1].Extra["attempt"])
+
+	tr.AssertExpectations(t)
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	tr := new(mockTransport)
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+
+	tr.On("RoundTrip", req).Return(&http.Response{StatusCode: http.StatusInternalServerError}, nil).Times(2)
+
+	client := New(
+		WithTransport(tr),
+		WithRetry(RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}),
+	)
+
+	var header servertiming.Header
+	resp, err := client.Client(servertiming.NewContext(req.Context(), &header)).Do(req)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Len(t, header.Metrics, 2)
+
+	tr.AssertExpectations(t)
+}
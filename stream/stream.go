@@ -0,0 +1,193 @@
+// Package stream provides a clienttiming.Notifier that broadcasts every
+// completed client round trip as a Server-Sent Events stream, together with
+// a small HTML page that renders it as a live timeline. It is meant for
+// local development, as a "witness"-style view of a service's outbound HTTP
+// traffic without needing to inspect response headers manually.
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+)
+
+// record is the JSON representation of a single completed round trip sent
+// to every connected client
+type record struct {
+	Name     string    `json:"name"`
+	Desc     string    `json:"desc"`
+	Source   string    `json:"source,omitempty"`
+	Start    time.Time `json:"start"`
+	Duration float64   `json:"duration"`
+	Code     int       `json:"code,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Upstream []record  `json:"upstream,omitempty"`
+}
+
+// clientBuffer is the size of each subscriber's channel. Once full, the
+// oldest pending record is dropped to make room for the new one, so a slow
+// browser tab never blocks the instrumented client.
+const clientBuffer = 64
+
+// Stream is an http.Handler that serves a live view of round trips, and a
+// clienttiming.Notifier that feeds it
+type Stream struct {
+	mu      sync.Mutex
+	clients map[chan record]struct{}
+	mux     *http.ServeMux
+}
+
+// New returns a Stream ready to be used as both a clienttiming.Notifier
+// (via WithNotifier) and an http.Handler, typically mounted on a debug port
+func New() *Stream {
+	s := &Stream{clients: make(map[chan record]struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveIndex)
+	mux.HandleFunc("/events", s.serveEvents)
+	s.mux = mux
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Notify implements clienttiming.Notifier
+func (s *Stream) Notify(metric *servertiming.Metric, req *http.Request, resp *http.Response, err error) {
+	rec := record{
+		Name:     metric.Name,
+		Desc:     metric.Desc,
+		Source:   metric.Extra["source"],
+		Start:    time.Now().Add(-metric.Duration),
+		Duration: metric.Duration.Seconds() * 1000,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if resp != nil {
+		rec.Code = resp.StatusCode
+		if upstream, parseErr := servertiming.ParseHeader(resp.Header.Get(servertiming.HeaderKey)); parseErr == nil {
+			for _, m := range upstream.Metrics {
+				rec.Upstream = append(rec.Upstream, record{
+					Name:     m.Name,
+					Desc:     m.Desc,
+					Source:   m.Extra["source"],
+					Duration: m.Duration.Seconds() * 1000,
+				})
+			}
+		}
+	}
+	s.broadcast(rec)
+}
+
+// broadcast sends rec to every connected client, dropping the oldest
+// buffered record for clients that are not keeping up
+func (s *Stream) broadcast(rec record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.clients {
+		select {
+		case ch <- rec:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- rec:
+			default:
+			}
+		}
+	}
+}
+
+// serveEvents handles GET /events, streaming records as they are notified
+func (s *Stream) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan record, clientBuffer)
+	s.mu.Lock()
+	s.clients[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, ch)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case rec := <-ch:
+			b, err := json.Marshal(rec)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", b)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// serveIndex handles GET /, rendering a minimal flame-chart-style timeline
+// of the live stream
+func (s *Stream) serveIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(indexHTML))
+}
+
+const indexHTML = `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>client-timing stream</title>
+<style>
+body { font-family: monospace; background: #111; color: #eee; }
+.row { position: relative; height: 20px; margin: 2px 0; background: #222; }
+.bar { position: absolute; top: 0; height: 100%; background: #4a9; }
+.label { position: absolute; left: 4px; top: 2px; font-size: 11px; color: #fff; }
+</style>
+</head>
+<body>
+<h3>Live client round trips</h3>
+<div id="timeline"></div>
+<script>
+var es = new EventSource('events');
+var timeline = document.getElementById('timeline');
+var maxDur = 1;
+es.onmessage = function(e) {
+  var rec = JSON.parse(e.data);
+  maxDur = Math.max(maxDur, rec.duration);
+  var row = document.createElement('div');
+  row.className = 'row';
+  var bar = document.createElement('div');
+  bar.className = 'bar';
+  bar.style.width = Math.min(100, rec.duration / maxDur * 100) + '%';
+  var label = document.createElement('div');
+  label.className = 'label';
+  label.textContent = rec.name + ' ' + rec.desc + ' (' + rec.duration.toFixed(1) + 'ms)' + (rec.error ? ' ERROR: ' + rec.error : ' ' + rec.code);
+  row.appendChild(bar);
+  row.appendChild(label);
+  timeline.insertBefore(row, timeline.firstChild);
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,69 @@
+package stream
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/go-server-timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamNotifyAndServe(t *testing.T) {
+	t.Parallel()
+
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	metric := &servertiming.Metric{Name: "example.com", Desc: "GET /", Duration: 12 * time.Millisecond}
+
+	// give the subscriber goroutine a moment to register before notifying
+	time.Sleep(10 * time.Millisecond)
+	s.Notify(metric, nil, &http.Response{StatusCode: http.StatusOK}, nil)
+
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	line := scanner.Text()
+
+	assert.True(t, strings.HasPrefix(line, "data: "))
+	assert.Contains(t, line, `"name":"example.com"`)
+	assert.Contains(t, line, `"code":200`)
+}
+
+func TestStreamIndex(t *testing.T) {
+	t.Parallel()
+
+	s := New()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "EventSource")
+}
+
+func TestStreamDropsOldestForSlowClient(t *testing.T) {
+	t.Parallel()
+
+	s := New()
+	ch := make(chan record, 1)
+	s.clients[ch] = struct{}{}
+
+	s.broadcast(record{Name: "first"})
+	s.broadcast(record{Name: "second"})
+
+	select {
+	case rec := <-ch:
+		assert.Equal(t, "second", rec.Name)
+	default:
+		t.Fatal("expected a buffered record")
+	}
+}
@@ -0,0 +1,140 @@
+package clienttiming
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportConfig configures NewTransport. Zero-valued fields fall back to
+// the net/http.Transport defaults for that field.
+type TransportConfig struct {
+	// DialTimeout bounds establishing the TCP connection
+	DialTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period for the dialer
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout bounds the wait for the response headers,
+	// after the request (including its body, if any) has been written.
+	ResponseHeaderTimeout time.Duration
+	// ExpectContinueTimeout bounds the wait for a 100-continue response
+	ExpectContinueTimeout time.Duration
+	// IdleConnTimeout bounds how long an idle connection is kept in the pool
+	IdleConnTimeout time.Duration
+	// MaxIdleConns caps idle connections across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections per host
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps total (idle and active) connections per host
+	MaxConnsPerHost int
+	// RootCAs, if set, replaces the system cert pool for TLS verification
+	RootCAs *x509.CertPool
+	// TimeToFirstByte, if set, cancels the request if no byte of the
+	// response body is read within this long after the response headers
+	// arrive. Unlike ResponseHeaderTimeout, which the net/http.Transport
+	// enforces natively, this is enforced by wrapping the response body,
+	// since the transport has no hook for the read that follows.
+	TimeToFirstByte time.Duration
+}
+
+// NewTransport builds an http.RoundTripper from cfg, so callers of
+// clienttiming.New don't have to re-derive the same dial/TLS/pool tuning
+// boilerplate every time they want it production ready. The result is a
+// *http.Transport, unless TimeToFirstByte is set, in which case it is
+// wrapped to enforce that deadline.
+func NewTransport(cfg TransportConfig) http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.KeepAlive,
+	}
+
+	t := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		ExpectContinueTimeout: cfg.ExpectContinueTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+	}
+
+	if cfg.RootCAs != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: cfg.RootCAs}
+	}
+
+	if cfg.TimeToFirstByte <= 0 {
+		return t
+	}
+	return &firstByteTransport{inner: t, timeout: cfg.TimeToFirstByte}
+}
+
+// firstByteTransport wraps an http.RoundTripper, canceling the request's
+// context if no byte of the response body is read within timeout of the
+// response headers arriving
+type firstByteTransport struct {
+	inner   http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *firstByteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	resp, err := t.inner.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &firstByteBody{
+		ReadCloser: resp.Body,
+		timer:      time.AfterFunc(t.timeout, cancel),
+		cancel:     cancel,
+	}
+	return resp, nil
+}
+
+// firstByteBody wraps a response body with a deadline timer that is
+// disarmed as soon as the first byte is read, so it only ever fires while
+// the caller is still waiting for the body to start. Closing the body
+// releases the context it was handed, so a caller whose own context is
+// long-lived (e.g. a server request's context) never ends up with a
+// cancelCtx pinned to it for the lifetime of that context.
+type firstByteBody struct {
+	io.ReadCloser
+	timer  *time.Timer
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// Read implements io.Reader
+func (b *firstByteBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.disarm()
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *firstByteBody) Close() error {
+	b.disarm()
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// disarm stops the deadline timer, if it hasn't already fired; the request
+// context stays live so the rest of the body can still be read
+func (b *firstByteBody) disarm() {
+	b.once.Do(func() {
+		b.timer.Stop()
+	})
+}
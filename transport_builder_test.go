@@ -0,0 +1,89 @@
+package clienttiming
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	rt := NewTransport(TransportConfig{
+		DialTimeout:         time.Second,
+		TLSHandshakeTimeout: 2 * time.Second,
+		MaxIdleConnsPerHost: 5,
+	})
+
+	tr, ok := rt.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, tr.TLSHandshakeTimeout)
+	assert.Equal(t, 5, tr.MaxIdleConnsPerHost)
+}
+
+func TestNewTransportWithTimeToFirstByte(t *testing.T) {
+	t.Parallel()
+
+	rt := NewTransport(TransportConfig{TimeToFirstByte: time.Second})
+
+	_, ok := rt.(*firstByteTransport)
+	assert.True(t, ok)
+}
+
+func TestFirstByteTransportCancelsWhenBodyNeverRead(t *testing.T) {
+	t.Parallel()
+
+	var innerCtx context.Context
+	inner := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		innerCtx = req.Context()
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("hi"))}, nil
+	})
+
+	rt := &firstByteTransport{inner: inner, timeout: 10 * time.Millisecond}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	_, err := rt.RoundTrip(req)
+	require.Nil(t, err)
+
+	select {
+	case <-innerCtx.Done():
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("context was not canceled after the first-byte deadline")
+	}
+}
+
+func TestFirstByteTransportDisarmsOnRead(t *testing.T) {
+	t.Parallel()
+
+	var innerCtx context.Context
+	inner := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		innerCtx = req.Context()
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("hi"))}, nil
+	})
+
+	rt := &firstByteTransport{inner: inner, timeout: 10 * time.Millisecond}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://golang.org", nil)
+	resp, err := rt.RoundTrip(req)
+	require.Nil(t, err)
+
+	b, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	assert.Equal(t, "hi", string(b))
+
+	// reading the first byte disarms the deadline: the context must survive
+	// past it, since the rest of the body is read under that same context
+	time.Sleep(30 * time.Millisecond)
+	assert.Nil(t, innerCtx.Err())
+
+	// closing the body releases the context, rather than leaving it pinned
+	// to the caller's context until the caller's context itself is done
+	require.Nil(t, resp.Body.Close())
+	assert.Equal(t, context.Canceled, innerCtx.Err())
+}